@@ -0,0 +1,95 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import "context"
+
+const (
+	// VolumeOperationTraceKey is the PVC annotation used to carry the W3C
+	// traceparent linking the controller-side and node-side phases of the
+	// same logical volume operation, similar in spirit to VolumeResizerKey.
+	// The controller phase reads its span's traceparent with
+	// TraceParentFromContext after starting and writes it to this
+	// annotation; the node phase reads the annotation back into
+	// InstrumentationLabels.TraceParent before calling Run, so its span
+	// joins the same trace instead of starting a new one.
+	VolumeOperationTraceKey = "volume.kubernetes.io/operation-trace-id"
+)
+
+// InstrumentationLabels are the structured labels attached to every
+// Instrumentation callback, so a metrics or tracing backend can slice
+// latency and failures by operation, plugin and volume.
+type InstrumentationLabels struct {
+	OperationName string
+	PluginName    string
+	VolumeMode    string
+	PVCUID        string
+	PodUID        string
+	// TraceParent, if set, is the W3C traceparent read from the PVC's
+	// VolumeOperationTraceKey annotation. OnStart uses it to start this
+	// operation's span as part of the same trace as the phase that wrote
+	// it, rather than starting an unrelated trace.
+	TraceParent string
+	// Status is only populated for OnFinish, once the operation's outcome
+	// is known. It is "Succeeded" when detailedErr was nil, the
+	// ErrorStatus of detailedErr when it is an *OperationError (Pending,
+	// InProgress, Failed, Infeasible or TimedOut), and "Failed" for any
+	// other non-nil error.
+	Status string
+}
+
+const (
+	// StatusSucceeded is the InstrumentationLabels.Status reported for an
+	// operation that finished with a nil error.
+	StatusSucceeded = "Succeeded"
+)
+
+// Instrumentation lets callers observe the start, finish and panic of a
+// GeneratedOperations.Run without GeneratedOperations itself depending on a
+// particular metrics or tracing backend. The zero value disables
+// instrumentation: any nil callback is simply not invoked.
+type Instrumentation struct {
+	// OnStart is called before OperationFunc runs. It may return a
+	// derived context (for example one carrying a tracing span) that is
+	// passed to OperationFunc in place of the original.
+	OnStart func(ctx context.Context, labels InstrumentationLabels) context.Context
+
+	// OnFinish is called after OperationFunc returns, as the last step of
+	// Run, with the wall-clock duration of Run measured from before OnStart
+	// until after CompleteFunc and EventRecorderFunc have returned.
+	OnFinish func(ctx context.Context, labels InstrumentationLabels, durationSeconds float64)
+
+	// OnPanic is called if OperationFunc panics, before the panic is
+	// turned into detailedErr by RecoverFromPanic.
+	OnPanic func(ctx context.Context, labels InstrumentationLabels, panicValue interface{})
+}
+
+// ResolveOperationStatus computes the InstrumentationLabels.Status that
+// GeneratedOperations.Run passes to Instrumentation.OnFinish for a given
+// detailedErr: StatusSucceeded if err is nil, the ErrorStatus of err when it
+// is an *OperationError, and StatusFailed for any other non-nil error.
+// NewDefaultInstrumentation's OnFinish passes this straight through as the
+// duration histogram's "status" label.
+func ResolveOperationStatus(err error) string {
+	if err == nil {
+		return StatusSucceeded
+	}
+	if opErr, ok := AsOperationError(err); ok {
+		return string(opErr.Status)
+	}
+	return string(StatusFailed)
+}
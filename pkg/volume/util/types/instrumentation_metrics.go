@@ -0,0 +1,129 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var operationDurationSeconds = metrics.NewHistogramVec(
+	&metrics.HistogramOpts{
+		Subsystem:      "volume_operation",
+		Name:           "duration_seconds",
+		Help:           "Duration in seconds of volume operations, keyed by operation, plugin, volume mode and resulting status.",
+		Buckets:        metrics.ExponentialBuckets(0.1, 2, 15),
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"operation_name", "plugin_name", "volume_mode", "status"},
+)
+
+func init() {
+	legacyregistry.MustRegister(operationDurationSeconds)
+}
+
+// tracer links the controller-side and node-side phases of the same
+// logical volume operation, by way of the traceparent stashed on the PVC
+// under VolumeOperationTraceKey.
+var tracer = otel.Tracer("k8s.io/kubernetes/pkg/volume/util/types")
+
+// traceParentPropagator injects/extracts a span context to/from a single
+// W3C traceparent string, so it can travel through the PVC's
+// VolumeOperationTraceKey annotation instead of an in-process carrier.
+var traceParentPropagator = propagation.TraceContext{}
+
+// traceParentCarrier adapts a single "traceparent" value to the
+// propagation.TextMapCarrier the TraceContext propagator expects; tracestate
+// is intentionally not carried, since the PVC annotation holds one value.
+type traceParentCarrier struct {
+	traceParent string
+}
+
+func (c *traceParentCarrier) Get(key string) string {
+	if key == "traceparent" {
+		return c.traceParent
+	}
+	return ""
+}
+
+func (c *traceParentCarrier) Set(key, value string) {
+	if key == "traceparent" {
+		c.traceParent = value
+	}
+}
+
+func (c *traceParentCarrier) Keys() []string {
+	return []string{"traceparent"}
+}
+
+// TraceParentFromContext returns the W3C traceparent for the span carried
+// by ctx (the context OperationFunc received from Run, after OnStart ran),
+// for the controller phase to persist to the PVC's VolumeOperationTraceKey
+// annotation so the node phase can join the same trace.
+func TraceParentFromContext(ctx context.Context) string {
+	carrier := &traceParentCarrier{}
+	traceParentPropagator.Inject(ctx, carrier)
+	return carrier.traceParent
+}
+
+// NewDefaultInstrumentation returns the Instrumentation used in production:
+// it records a Prometheus duration histogram per operation/plugin/status
+// and starts an OpenTelemetry span for every operation. When
+// InstrumentationLabels.TraceParent is set (the node phase read it back
+// from the PVC's VolumeOperationTraceKey annotation), the span joins that
+// trace instead of starting a new one, linking the controller-side and
+// node-side phases of the same logical operation together.
+func NewDefaultInstrumentation() Instrumentation {
+	return Instrumentation{
+		OnStart: func(ctx context.Context, labels InstrumentationLabels) context.Context {
+			if labels.TraceParent != "" {
+				ctx = traceParentPropagator.Extract(ctx, &traceParentCarrier{traceParent: labels.TraceParent})
+			}
+			ctx, _ = tracer.Start(ctx, labels.OperationName, trace.WithAttributes(
+				attribute.String("plugin_name", labels.PluginName),
+				attribute.String("volume_mode", labels.VolumeMode),
+				attribute.String("pvc_uid", labels.PVCUID),
+				attribute.String("pod_uid", labels.PodUID),
+			))
+			return ctx
+		},
+		OnFinish: func(ctx context.Context, labels InstrumentationLabels, durationSeconds float64) {
+			span := trace.SpanFromContext(ctx)
+			span.SetAttributes(attribute.String("status", string(labels.Status)))
+			span.End()
+
+			operationDurationSeconds.WithLabelValues(
+				labels.OperationName, labels.PluginName, labels.VolumeMode, string(labels.Status),
+			).Observe(durationSeconds)
+		},
+		OnPanic: func(ctx context.Context, labels InstrumentationLabels, panicValue interface{}) {
+			// OnFinish always runs after OnPanic (see GeneratedOperations.Run)
+			// and sets the final status attribute and ends the span, so this
+			// only needs to record the panic itself.
+			span := trace.SpanFromContext(ctx)
+			span.RecordError(fmt.Errorf("panic in volume operation %s: %v", labels.OperationName, panicValue))
+		},
+	}
+}
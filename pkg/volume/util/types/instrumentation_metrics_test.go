@@ -0,0 +1,75 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestMain installs an always-sampling SDK TracerProvider for this package's
+// tests. Without one, the default global TracerProvider is a no-op that
+// never allocates real trace/span IDs, so TraceParentFromContext would
+// return an empty traceparent and TestOnStartJoinsTraceParentFromAnnotation
+// would fail.
+func TestMain(m *testing.M) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample())))
+	os.Exit(m.Run())
+}
+
+// TestOnStartJoinsTraceParentFromAnnotation simulates the controller phase
+// of a two-phase operation recording its traceparent, and the node phase
+// reading that same value back (as it would from the PVC's
+// VolumeOperationTraceKey annotation) so its own span joins the same trace
+// instead of starting an unrelated one.
+func TestOnStartJoinsTraceParentFromAnnotation(t *testing.T) {
+	instrumentation := NewDefaultInstrumentation()
+
+	// Controller phase: no TraceParent yet, so OnStart begins a new trace.
+	controllerCtx := instrumentation.OnStart(context.Background(), InstrumentationLabels{
+		OperationName: "expand-volume",
+	})
+	traceParent := TraceParentFromContext(controllerCtx)
+	if traceParent == "" {
+		t.Fatal("TraceParentFromContext() returned empty traceparent after OnStart, want a non-empty value to persist on the PVC annotation")
+	}
+	controllerTraceID := trace.SpanContextFromContext(controllerCtx).TraceID().String()
+
+	// Node phase: reads the annotation written by the controller phase
+	// back into InstrumentationLabels.TraceParent before calling Run.
+	nodeCtx := instrumentation.OnStart(context.Background(), InstrumentationLabels{
+		OperationName: "expand-volume",
+		TraceParent:   traceParent,
+	})
+	nodeTraceID := trace.SpanContextFromContext(nodeCtx).TraceID().String()
+
+	if nodeTraceID != controllerTraceID {
+		t.Errorf("node phase trace-id = %s, want it to match controller phase trace-id %s (same logical operation)", nodeTraceID, controllerTraceID)
+	}
+
+	// Sanity check the annotation value itself looks like a traceparent,
+	// not an internal representation leaking out.
+	if !strings.HasPrefix(traceParent, "00-") {
+		t.Errorf("traceparent = %q, want it to start with the W3C version prefix %q", traceParent, "00-")
+	}
+}
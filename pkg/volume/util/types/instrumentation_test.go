@@ -0,0 +1,68 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestResolveOperationStatus covers the status NewDefaultInstrumentation's
+// OnFinish reports as the duration histogram's "status" label, for each
+// shape of error GeneratedOperations.Run can finish with.
+func TestResolveOperationStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "nil error maps to succeeded",
+			err:  nil,
+			want: StatusSucceeded,
+		},
+		{
+			name: "generic error maps to failed",
+			err:  errors.New("boom"),
+			want: string(StatusFailed),
+		},
+		{
+			name: "operation error status is passed through",
+			err:  NewControllerResizeInProgressError(errors.New("still resizing")),
+			want: string(StatusInProgress),
+		},
+		{
+			name: "infeasible operation error status is passed through",
+			err:  NewInfeasibleError(ControllerPhase, ExpandOp, errors.New("too small")),
+			want: string(StatusInfeasible),
+		},
+		{
+			name: "wrapped operation error status is passed through",
+			err:  fmt.Errorf("while expanding: %w", NewNodeResizeFailedError(errors.New("node expand failed"))),
+			want: string(StatusFailed),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveOperationStatus(tt.err); got != tt.want {
+				t.Errorf("ResolveOperationStatus(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Phase identifies which side of a two-phase volume operation (controller or
+// node) produced an OperationError.
+type Phase string
+
+const (
+	// ControllerPhase means the error originated in the controller-side
+	// half of a two-phase operation (e.g. controller-expand, attach).
+	ControllerPhase Phase = "Controller"
+
+	// NodePhase means the error originated in the node/kubelet-side half
+	// of a two-phase operation (e.g. node-expand, mount).
+	NodePhase Phase = "Node"
+)
+
+// Op identifies the kind of volume operation that failed.
+type Op string
+
+const (
+	AttachOp  Op = "Attach"
+	MountOp   Op = "Mount"
+	ExpandOp  Op = "Expand"
+	ModifyOp  Op = "Modify"
+	UnmountOp Op = "Unmount"
+	DetachOp  Op = "Detach"
+)
+
+// ErrorStatus mirrors the PVC allocatedResourceStatuses / CSI status
+// vocabulary so an OperationError can be translated directly into the
+// condition reported on the PVC.
+type ErrorStatus string
+
+const (
+	StatusPending    ErrorStatus = "Pending"
+	StatusInProgress ErrorStatus = "InProgress"
+	StatusFailed     ErrorStatus = "Failed"
+	StatusInfeasible ErrorStatus = "Infeasible"
+	StatusTimedOut   ErrorStatus = "TimedOut"
+)
+
+// RetryPolicy tells the operation executor what to do with a volume's
+// shared exponential backoff once an operation finishes with this error.
+type RetryPolicy string
+
+const (
+	// Retryable means the operation should be retried, subject to the
+	// existing exponential backoff.
+	Retryable RetryPolicy = "Retryable"
+
+	// TerminalInfeasible means the operation cannot succeed no matter how
+	// many times it is retried (e.g. a resize below the current size) and
+	// should not be retried at all.
+	TerminalInfeasible RetryPolicy = "TerminalInfeasible"
+
+	// BackoffReset means the operation made enough progress that the
+	// shared exponential backoff for this volume should be cleared.
+	BackoffReset RetryPolicy = "BackoffReset"
+)
+
+// OperationError is a structured, machine-readable error describing the
+// outcome of a volume operation. It carries enough information for the
+// operation executor to decide whether to keep backing off, reset the
+// backoff, or stop retrying altogether, and for callers to translate it
+// into a PVC allocatedResourceStatus.
+type OperationError struct {
+	Phase       Phase
+	Op          Op
+	Status      ErrorStatus
+	RetryPolicy RetryPolicy
+	cause       error
+}
+
+var _ error = &OperationError{}
+
+// NewOperationError returns a new OperationError wrapping cause.
+func NewOperationError(phase Phase, op Op, status ErrorStatus, retryPolicy RetryPolicy, cause error) *OperationError {
+	return &OperationError{
+		Phase:       phase,
+		Op:          op,
+		Status:      status,
+		RetryPolicy: retryPolicy,
+		cause:       cause,
+	}
+}
+
+func (e *OperationError) Error() string {
+	if e.cause == nil {
+		return fmt.Sprintf("%s%s %s", e.Phase, e.Op, e.Status)
+	}
+	return fmt.Sprintf("%s%s %s: %v", e.Phase, e.Op, e.Status, e.cause)
+}
+
+// Unwrap allows errors.Is/errors.As to see through an OperationError to its
+// underlying cause.
+func (e *OperationError) Unwrap() error {
+	return e.cause
+}
+
+// NewControllerResizeInProgressError returns an OperationError indicating a
+// controller-side resize is still in progress.
+func NewControllerResizeInProgressError(cause error) *OperationError {
+	return NewOperationError(ControllerPhase, ExpandOp, StatusInProgress, Retryable, cause)
+}
+
+// NewControllerResizeFailedError returns an OperationError indicating a
+// controller-side resize failed and may be retried.
+func NewControllerResizeFailedError(cause error) *OperationError {
+	return NewOperationError(ControllerPhase, ExpandOp, StatusFailed, Retryable, cause)
+}
+
+// NewNodeResizePendingError returns an OperationError indicating a volume is
+// waiting for its node-side resize to be attempted.
+func NewNodeResizePendingError(cause error) *OperationError {
+	return NewOperationError(NodePhase, ExpandOp, StatusPending, Retryable, cause)
+}
+
+// NewNodeResizeInProgressError returns an OperationError indicating a
+// node-side resize is still in progress.
+func NewNodeResizeInProgressError(cause error) *OperationError {
+	return NewOperationError(NodePhase, ExpandOp, StatusInProgress, Retryable, cause)
+}
+
+// NewNodeResizeFailedError returns an OperationError indicating a node-side
+// resize failed and may be retried.
+func NewNodeResizeFailedError(cause error) *OperationError {
+	return NewOperationError(NodePhase, ExpandOp, StatusFailed, Retryable, cause)
+}
+
+// NewModifyVolumeInProgressError returns an OperationError indicating a
+// ModifyVolume request is still in progress.
+func NewModifyVolumeInProgressError(cause error) *OperationError {
+	return NewOperationError(ControllerPhase, ModifyOp, StatusInProgress, Retryable, cause)
+}
+
+// NewInfeasibleError returns an OperationError for phase/op that cannot
+// succeed no matter how many times it is retried.
+func NewInfeasibleError(phase Phase, op Op, cause error) *OperationError {
+	return NewOperationError(phase, op, StatusInfeasible, TerminalInfeasible, cause)
+}
+
+// IsInfeasible returns true if err is an OperationError whose RetryPolicy
+// marks it as terminally infeasible, i.e. retrying it will never help.
+func IsInfeasible(err error) bool {
+	opErr, ok := AsOperationError(err)
+	return ok && opErr.RetryPolicy == TerminalInfeasible
+}
+
+// IsRetryable returns true if err should be retried. This is true both for
+// RetryPolicy Retryable (retry using the existing exponential backoff) and
+// BackoffReset (the operation made progress and should still be retried,
+// just with that backoff cleared first - see ShouldResetBackoff). Errors
+// that aren't an OperationError retain today's behavior of always being
+// retried.
+func IsRetryable(err error) bool {
+	opErr, ok := AsOperationError(err)
+	if !ok {
+		return true
+	}
+	return opErr.RetryPolicy == Retryable || opErr.RetryPolicy == BackoffReset
+}
+
+// ShouldResetBackoff returns true if err is an OperationError whose
+// RetryPolicy is BackoffReset, meaning the operation made enough progress
+// (e.g. finished its controller-side phase) that the volume's shared
+// exponential backoff should be cleared before the next retry.
+func ShouldResetBackoff(err error) bool {
+	opErr, ok := AsOperationError(err)
+	return ok && opErr.RetryPolicy == BackoffReset
+}
+
+// AsOperationError unwraps err looking for an *OperationError.
+func AsOperationError(err error) (*OperationError, bool) {
+	var opErr *OperationError
+	ok := errors.As(err, &opErr)
+	return opErr, ok
+}
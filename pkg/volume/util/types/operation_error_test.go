@@ -0,0 +1,134 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsRetryableIsInfeasibleShouldResetBackoff(t *testing.T) {
+	cause := errors.New("underlying failure")
+
+	tests := []struct {
+		name             string
+		err              error
+		wantRetryable    bool
+		wantInfeasible   bool
+		wantResetBackoff bool
+	}{
+		{
+			name:             "nil error is retryable",
+			err:              nil,
+			wantRetryable:    true,
+			wantInfeasible:   false,
+			wantResetBackoff: false,
+		},
+		{
+			name:             "plain error is retryable",
+			err:              cause,
+			wantRetryable:    true,
+			wantInfeasible:   false,
+			wantResetBackoff: false,
+		},
+		{
+			name:             "Retryable operation error",
+			err:              NewOperationError(ControllerPhase, ExpandOp, StatusInProgress, Retryable, cause),
+			wantRetryable:    true,
+			wantInfeasible:   false,
+			wantResetBackoff: false,
+		},
+		{
+			name:             "TerminalInfeasible operation error",
+			err:              NewOperationError(ControllerPhase, ExpandOp, StatusInfeasible, TerminalInfeasible, cause),
+			wantRetryable:    false,
+			wantInfeasible:   true,
+			wantResetBackoff: false,
+		},
+		{
+			name:             "BackoffReset operation error is retryable but not infeasible",
+			err:              NewOperationError(NodePhase, ExpandOp, StatusInProgress, BackoffReset, cause),
+			wantRetryable:    true,
+			wantInfeasible:   false,
+			wantResetBackoff: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.wantRetryable {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.wantRetryable)
+			}
+			if got := IsInfeasible(tt.err); got != tt.wantInfeasible {
+				t.Errorf("IsInfeasible(%v) = %v, want %v", tt.err, got, tt.wantInfeasible)
+			}
+			if got := ShouldResetBackoff(tt.err); got != tt.wantResetBackoff {
+				t.Errorf("ShouldResetBackoff(%v) = %v, want %v", tt.err, got, tt.wantResetBackoff)
+			}
+		})
+	}
+}
+
+func TestAsOperationError(t *testing.T) {
+	cause := errors.New("underlying failure")
+	opErr := NewControllerResizeFailedError(cause)
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr *OperationError
+		wantOk  bool
+	}{
+		{
+			name:    "nil error",
+			err:     nil,
+			wantErr: nil,
+			wantOk:  false,
+		},
+		{
+			name:    "plain error",
+			err:     cause,
+			wantErr: nil,
+			wantOk:  false,
+		},
+		{
+			name:    "bare operation error",
+			err:     opErr,
+			wantErr: opErr,
+			wantOk:  true,
+		},
+		{
+			name:    "wrapped operation error",
+			err:     fmt.Errorf("while expanding: %w", opErr),
+			wantErr: opErr,
+			wantOk:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := AsOperationError(tt.err)
+			if ok != tt.wantOk {
+				t.Fatalf("AsOperationError(%v) ok = %v, want %v", tt.err, ok, tt.wantOk)
+			}
+			if ok && got != tt.wantErr {
+				t.Errorf("AsOperationError(%v) = %v, want %v", tt.err, got, tt.wantErr)
+			}
+		})
+	}
+}
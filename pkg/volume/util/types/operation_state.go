@@ -0,0 +1,137 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import "fmt"
+
+// OperationState describes where a tracked volume operation is in its
+// lifecycle. OperationStatus only distinguishes whether an operation has
+// finished; OperationState models every step it passes through so the
+// operation executor can enforce valid transitions and checkpoint progress
+// across kubelet restarts.
+type OperationState string
+
+const (
+	// OperationQueued means the operation has been accepted but has not
+	// started running yet.
+	OperationQueued OperationState = "Queued"
+
+	// OperationRunning means the operation is actively executing.
+	OperationRunning OperationState = "Running"
+
+	// OperationAwaitingNodeAck means the controller-side half of a
+	// two-phase operation finished and the operation is now waiting on
+	// the node to acknowledge and perform its half (e.g. node-expand
+	// after controller-expand).
+	OperationAwaitingNodeAck OperationState = "AwaitingNodeAck"
+
+	// OperationSucceeded means the operation, including both phases for a
+	// two-phase operation, completed successfully.
+	OperationSucceeded OperationState = "Succeeded"
+
+	// OperationFailed means the operation finished with a terminal
+	// failure and will not be retried.
+	OperationFailed OperationState = "Failed"
+
+	// OperationCancelled means the operation was cancelled before it
+	// could finish. GeneratedOperations.Run never makes this transition
+	// itself; the caller that owns the operation's CancelFunc is
+	// responsible for recording it once IsOperationCancelled(detailedErr)
+	// is true.
+	OperationCancelled OperationState = "Cancelled"
+)
+
+// operationStateTransitions enumerates the states an operation may move to
+// from a given state. A transition not listed here is rejected by
+// CanTransitionOperationState.
+var operationStateTransitions = map[OperationState][]OperationState{
+	OperationQueued:          {OperationRunning, OperationCancelled},
+	OperationRunning:         {OperationAwaitingNodeAck, OperationSucceeded, OperationFailed, OperationCancelled},
+	OperationAwaitingNodeAck: {OperationRunning, OperationSucceeded, OperationFailed, OperationCancelled},
+	OperationSucceeded:       {},
+	OperationFailed:          {},
+	OperationCancelled:       {},
+}
+
+// CanTransitionOperationState reports whether an operation is allowed to
+// move from one state to another.
+func CanTransitionOperationState(from, to OperationState) bool {
+	for _, allowed := range operationStateTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckpointData is the minimal state the operation executor needs to
+// resume a two-phase operation (controller-expand -> node-expand,
+// controller-modify -> node-modify) after a kubelet restart, instead of
+// restarting the operation from scratch.
+type CheckpointData struct {
+	// State is the OperationState the operation was in when checkpointed.
+	State OperationState
+
+	// TargetSize is the requested size for a resize operation, if any.
+	TargetSize string
+
+	// VACName is the target VolumeAttributesClass name for a modify
+	// operation, if any.
+	VACName string
+
+	// NodeName is the node an attach/detach operation targets, if any.
+	NodeName string
+}
+
+// Checkpointer persists and restores in-flight operation state so a
+// restarted kubelet can resume long-running two-phase operations rather
+// than starting over. Implementations key entries the same way the
+// operation executor already deduplicates in-flight operations, by
+// UniquePodName or UniquePVCName.
+type Checkpointer interface {
+	// SaveCheckpoint persists data for the operation identified by key,
+	// overwriting any previously saved data for the same key.
+	SaveCheckpoint(key string, data CheckpointData) error
+
+	// LoadCheckpoint returns the previously saved data for key. The
+	// second return value is false if no checkpoint exists for key.
+	LoadCheckpoint(key string) (CheckpointData, bool, error)
+
+	// DeleteCheckpoint removes any saved data for key. It is a no-op if
+	// no checkpoint exists for key.
+	DeleteCheckpoint(key string) error
+}
+
+// ResumeCheckpointedState loads the checkpoint persisted for key and
+// validates that the operation may legally resume from its checkpointed
+// State into next, returning an error instead of the checkpoint if it
+// cannot. The operation executor calls this on kubelet restart, in place of
+// unconditionally restarting the operation from OperationQueued, so a
+// two-phase operation continues from wherever it was checkpointed.
+func ResumeCheckpointedState(checkpointer Checkpointer, key string, next OperationState) (CheckpointData, error) {
+	data, ok, err := checkpointer.LoadCheckpoint(key)
+	if err != nil {
+		return CheckpointData{}, err
+	}
+	if !ok {
+		return CheckpointData{}, fmt.Errorf("no checkpoint found for %q", key)
+	}
+	if !CanTransitionOperationState(data.State, next) {
+		return CheckpointData{}, fmt.Errorf("cannot resume operation %q from %s to %s", key, data.State, next)
+	}
+	return data, nil
+}
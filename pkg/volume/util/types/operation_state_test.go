@@ -0,0 +1,168 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCanTransitionOperationState(t *testing.T) {
+	tests := []struct {
+		from, to OperationState
+		want     bool
+	}{
+		{OperationQueued, OperationRunning, true},
+		{OperationQueued, OperationCancelled, true},
+		{OperationQueued, OperationAwaitingNodeAck, false},
+		{OperationQueued, OperationSucceeded, false},
+		{OperationRunning, OperationAwaitingNodeAck, true},
+		{OperationRunning, OperationSucceeded, true},
+		{OperationRunning, OperationFailed, true},
+		{OperationRunning, OperationCancelled, true},
+		{OperationRunning, OperationQueued, false},
+		{OperationAwaitingNodeAck, OperationRunning, true},
+		{OperationAwaitingNodeAck, OperationSucceeded, true},
+		{OperationAwaitingNodeAck, OperationFailed, true},
+		{OperationAwaitingNodeAck, OperationCancelled, true},
+		{OperationAwaitingNodeAck, OperationQueued, false},
+		{OperationSucceeded, OperationRunning, false},
+		{OperationFailed, OperationRunning, false},
+		{OperationCancelled, OperationRunning, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s->%s", tt.from, tt.to), func(t *testing.T) {
+			if got := CanTransitionOperationState(tt.from, tt.to); got != tt.want {
+				t.Errorf("CanTransitionOperationState(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeCheckpointer is an in-memory Checkpointer used to exercise the
+// save/load/delete contract in tests.
+type fakeCheckpointer struct {
+	data map[string]CheckpointData
+}
+
+func newFakeCheckpointer() *fakeCheckpointer {
+	return &fakeCheckpointer{data: make(map[string]CheckpointData)}
+}
+
+func (f *fakeCheckpointer) SaveCheckpoint(key string, data CheckpointData) error {
+	f.data[key] = data
+	return nil
+}
+
+func (f *fakeCheckpointer) LoadCheckpoint(key string) (CheckpointData, bool, error) {
+	data, ok := f.data[key]
+	return data, ok, nil
+}
+
+func (f *fakeCheckpointer) DeleteCheckpoint(key string) error {
+	delete(f.data, key)
+	return nil
+}
+
+var _ Checkpointer = &fakeCheckpointer{}
+
+// TestResumeCheckpointedStateAcrossPhases drives ResumeCheckpointedState
+// through a full controller-expand -> node-expand operation surviving a
+// kubelet restart midway: OperationQueued -> OperationRunning (controller
+// phase starts) -> OperationAwaitingNodeAck (controller phase checkpoints
+// and "the kubelet restarts") -> OperationRunning (node phase resumes from
+// the checkpoint) -> OperationSucceeded (node phase completes), checkpoint
+// cleaned up at the end.
+func TestResumeCheckpointedStateAcrossPhases(t *testing.T) {
+	const key = "pvc-uid-1"
+	checkpointer := newFakeCheckpointer()
+
+	state := OperationQueued
+	advance := func(next OperationState) {
+		t.Helper()
+		if !CanTransitionOperationState(state, next) {
+			t.Fatalf("CanTransitionOperationState(%s, %s) = false, want true", state, next)
+		}
+		state = next
+	}
+
+	advance(OperationRunning)
+
+	// The controller phase makes progress and checkpoints before the
+	// kubelet restarts.
+	advance(OperationAwaitingNodeAck)
+	if err := checkpointer.SaveCheckpoint(key, CheckpointData{
+		State:      state,
+		TargetSize: "10Gi",
+	}); err != nil {
+		t.Fatalf("SaveCheckpoint() returned error: %v", err)
+	}
+
+	// Simulate the kubelet restarting: a fresh replay loop no longer has
+	// the in-memory state above, so a new process resumes purely from the
+	// checkpoint instead of restarting at OperationQueued.
+	data, err := ResumeCheckpointedState(checkpointer, key, OperationRunning)
+	if err != nil {
+		t.Fatalf("ResumeCheckpointedState() returned error: %v", err)
+	}
+	if data.State != OperationAwaitingNodeAck {
+		t.Fatalf("replayed checkpoint State = %s, want %s", data.State, OperationAwaitingNodeAck)
+	}
+	if data.TargetSize != "10Gi" {
+		t.Fatalf("replayed checkpoint TargetSize = %q, want %q", data.TargetSize, "10Gi")
+	}
+	state = OperationRunning // the node phase has resumed
+
+	advance(OperationSucceeded)
+
+	if err := checkpointer.DeleteCheckpoint(key); err != nil {
+		t.Fatalf("DeleteCheckpoint() returned error: %v", err)
+	}
+	if _, ok, err := checkpointer.LoadCheckpoint(key); err != nil {
+		t.Fatalf("LoadCheckpoint() after delete returned error: %v", err)
+	} else if ok {
+		t.Fatalf("LoadCheckpoint(%q) found a checkpoint after DeleteCheckpoint, want none", key)
+	}
+}
+
+// TestResumeCheckpointedStateRejectsIllegalResume asserts that resuming
+// from a checkpoint whose State has no legal transition to the requested
+// next state is rejected, rather than silently re-running a finished
+// operation.
+func TestResumeCheckpointedStateRejectsIllegalResume(t *testing.T) {
+	const key = "pvc-uid-2"
+	checkpointer := newFakeCheckpointer()
+
+	if err := checkpointer.SaveCheckpoint(key, CheckpointData{State: OperationSucceeded}); err != nil {
+		t.Fatalf("SaveCheckpoint() returned error: %v", err)
+	}
+
+	if _, err := ResumeCheckpointedState(checkpointer, key, OperationRunning); err == nil {
+		t.Fatal("ResumeCheckpointedState() succeeded resuming OperationSucceeded into OperationRunning, want error")
+	}
+}
+
+// TestResumeCheckpointedStateNoCheckpoint asserts that resuming with no
+// saved checkpoint is rejected rather than silently treated as a fresh
+// operation.
+func TestResumeCheckpointedStateNoCheckpoint(t *testing.T) {
+	checkpointer := newFakeCheckpointer()
+
+	if _, err := ResumeCheckpointedState(checkpointer, "missing-key", OperationRunning); err == nil {
+		t.Fatal("ResumeCheckpointedState() succeeded with no checkpoint saved, want error")
+	}
+}
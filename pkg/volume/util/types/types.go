@@ -18,6 +18,10 @@ limitations under the License.
 package types
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
 )
@@ -33,13 +37,62 @@ type UniquePVCName types.UID
 type GeneratedOperations struct {
 	// Name of operation - could be used for resetting shared exponential backoff
 	OperationName     string
-	OperationFunc     func() (eventErr error, detailedErr error)
+	OperationFunc     func(ctx context.Context) (eventErr error, detailedErr error)
 	EventRecorderFunc func(*error)
 	CompleteFunc      func(*error)
+
+	// Instrumentation, if set, is invoked around OperationFunc to emit
+	// metrics and tracing spans for this operation; see
+	// NewDefaultInstrumentation for the production implementation. It is
+	// optional so existing callers that build a GeneratedOperations
+	// without it keep working unchanged.
+	Instrumentation Instrumentation
+	// Labels identify this operation to Instrumentation. OperationName is
+	// filled in from the field above and need not be set here.
+	Labels InstrumentationLabels
+
+	// Timeout, if non-zero, bounds how long OperationFunc may run. It is
+	// derived from the plugin's declared timeout for this kind of
+	// operation (e.g. the attach/detach timeout); once it elapses ctx is
+	// cancelled, so a stuck volume plugin call (a hung NFS mount, a slow
+	// CSI RPC) is unblocked instead of wedging the operation executor's
+	// worker until it panics or the process exits.
+	Timeout time.Duration
 }
 
-// Run executes the operations and its supporting functions
-func (o *GeneratedOperations) Run() (eventErr, detailedErr error) {
+// Run executes the operations and its supporting functions, passing
+// OperationFunc a context derived from ctx that is cancelled once Timeout
+// elapses (if set).
+//
+// GeneratedOperations does not hold its own CancelFunc: it is constructed
+// and returned by value at every volume plugin call site, so embedding one
+// here would make those copies unsafe. Callers that need to cancel a
+// running operation before its Timeout should derive ctx with
+// context.WithCancel before calling Run and keep the CancelFunc themselves,
+// the same way the operation executor already tracks other per-operation
+// state.
+//
+// Run itself never transitions a tracked OperationState to
+// OperationCancelled, since it does not own the cancellation decision.
+// When a caller's own CancelFunc fires, OperationFunc observes ctx.Done()
+// and returns ctx.Err() as detailedErr; the caller should check that
+// detailedErr with IsOperationCancelled and record OperationCancelled
+// itself.
+//
+// detailedErr may be an *OperationError; callers such as the operation
+// executor should use AsOperationError/IsInfeasible/IsRetryable rather than
+// assuming a plain error when deciding whether to keep backing off, reset
+// the backoff, or mark the operation as terminally failed.
+func (o *GeneratedOperations) Run(ctx context.Context) (eventErr, detailedErr error) {
+	labels := o.Labels
+	labels.OperationName = o.OperationName
+	start := time.Now()
+	if o.Instrumentation.OnFinish != nil {
+		defer func() {
+			labels.Status = ResolveOperationStatus(detailedErr)
+			o.Instrumentation.OnFinish(ctx, labels, time.Since(start).Seconds())
+		}()
+	}
 	if o.CompleteFunc != nil {
 		defer o.CompleteFunc(&detailedErr)
 	}
@@ -48,7 +101,35 @@ func (o *GeneratedOperations) Run() (eventErr, detailedErr error) {
 	}
 	// Handle panic, if any, from operationFunc()
 	defer runtime.RecoverFromPanic(&detailedErr)
-	return o.OperationFunc()
+	if o.Instrumentation.OnPanic != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				o.Instrumentation.OnPanic(ctx, labels, r)
+				panic(r)
+			}
+		}()
+	}
+
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+
+	if o.Instrumentation.OnStart != nil {
+		ctx = o.Instrumentation.OnStart(ctx, labels)
+	}
+
+	return o.OperationFunc(ctx)
+}
+
+// IsOperationCancelled returns true if detailedErr is the ctx.Err() produced
+// by a caller's own CancelFunc firing while OperationFunc was running (see
+// the Run doc comment). The operation executor calls this to decide when to
+// transition a tracked operation to OperationCancelled, since Run does not
+// make that transition itself.
+func IsOperationCancelled(detailedErr error) bool {
+	return errors.Is(detailedErr, context.Canceled)
 }
 
 type OperationStatus string
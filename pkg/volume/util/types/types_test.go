@@ -0,0 +1,153 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGeneratedOperationsRunCompleteFuncCalledOnce(t *testing.T) {
+	tests := []struct {
+		name          string
+		operationFunc func(ctx context.Context) (error, error)
+		wantErr       bool
+	}{
+		{
+			name: "success",
+			operationFunc: func(ctx context.Context) (error, error) {
+				return nil, nil
+			},
+			wantErr: false,
+		},
+		{
+			name: "error",
+			operationFunc: func(ctx context.Context) (error, error) {
+				return errors.New("event error"), errors.New("detailed error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "panic",
+			operationFunc: func(ctx context.Context) (error, error) {
+				panic("operationFunc panicked")
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var completeCalls, eventRecorderCalls int
+			o := &GeneratedOperations{
+				OperationName: "test-op",
+				OperationFunc: tt.operationFunc,
+				CompleteFunc: func(detailedErr *error) {
+					completeCalls++
+				},
+				EventRecorderFunc: func(eventErr *error) {
+					eventRecorderCalls++
+				},
+			}
+
+			_, detailedErr := o.Run(context.Background())
+
+			if completeCalls != 1 {
+				t.Errorf("CompleteFunc called %d times, want exactly 1", completeCalls)
+			}
+			if eventRecorderCalls != 1 {
+				t.Errorf("EventRecorderFunc called %d times, want exactly 1", eventRecorderCalls)
+			}
+			if (detailedErr != nil) != tt.wantErr {
+				t.Errorf("detailedErr = %v, wantErr %v", detailedErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeneratedOperationsRunPanicRecoveredAfterOnPanic(t *testing.T) {
+	var onPanicCalled bool
+	var onPanicValue interface{}
+
+	o := &GeneratedOperations{
+		OperationName: "test-op",
+		OperationFunc: func(ctx context.Context) (error, error) {
+			panic("boom")
+		},
+		Instrumentation: Instrumentation{
+			OnPanic: func(ctx context.Context, labels InstrumentationLabels, panicValue interface{}) {
+				onPanicCalled = true
+				onPanicValue = panicValue
+			},
+		},
+	}
+
+	_, detailedErr := o.Run(context.Background())
+
+	if !onPanicCalled {
+		t.Fatal("OnPanic was not called for a panicking OperationFunc")
+	}
+	if onPanicValue != "boom" {
+		t.Errorf("OnPanic panicValue = %v, want %q", onPanicValue, "boom")
+	}
+	if detailedErr == nil {
+		t.Fatal("detailedErr is nil; RecoverFromPanic should have converted the panic into an error after OnPanic ran")
+	}
+}
+
+func TestGeneratedOperationsRunTimeoutCancelsContext(t *testing.T) {
+	o := &GeneratedOperations{
+		OperationName: "test-op",
+		Timeout:       10 * time.Millisecond,
+		OperationFunc: func(ctx context.Context) (error, error) {
+			<-ctx.Done()
+			return ctx.Err(), ctx.Err()
+		},
+	}
+
+	_, detailedErr := o.Run(context.Background())
+
+	if !errors.Is(detailedErr, context.DeadlineExceeded) {
+		t.Errorf("detailedErr = %v, want context.DeadlineExceeded", detailedErr)
+	}
+}
+
+// TestGeneratedOperationsRunCallerCancelSignalsIsOperationCancelled drives
+// the caller-owned cancellation path documented on Run: the caller derives
+// its own cancellable context, cancels it while OperationFunc is running,
+// and is expected to recognize that outcome with IsOperationCancelled so it
+// can record OperationCancelled itself (Run does not do this).
+func TestGeneratedOperationsRunCallerCancelSignalsIsOperationCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	o := &GeneratedOperations{
+		OperationName: "test-op",
+		OperationFunc: func(ctx context.Context) (error, error) {
+			cancel()
+			<-ctx.Done()
+			return ctx.Err(), ctx.Err()
+		},
+	}
+
+	_, detailedErr := o.Run(ctx)
+
+	if !IsOperationCancelled(detailedErr) {
+		t.Errorf("IsOperationCancelled(%v) = false, want true for a caller-cancelled context", detailedErr)
+	}
+}